@@ -0,0 +1,314 @@
+// Package aks provides a reusable Pulumi ComponentResource wrapping an AKS
+// cluster, its system/user node pools, and the Helm releases deployed onto
+// it once it is ready.
+package aks
+
+import (
+	"encoding/base64"
+
+	"github.com/pulumi/pulumi-azure-native-sdk/containerservice/v2"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v3/go/kubernetes"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v3/go/kubernetes/apiextensions"
+	helmv3 "github.com/pulumi/pulumi-kubernetes/sdk/v3/go/kubernetes/helm/v3"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v3/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ChartSpec describes a single Helm release to deploy into the cluster. It
+// mirrors the shape of the `charts` stack config key, one entry per release.
+type ChartSpec struct {
+	Name      string                 `json:"name"`
+	Chart     string                 `json:"chart"`
+	Repo      string                 `json:"repo"`
+	Version   string                 `json:"version"`
+	Namespace string                 `json:"namespace"`
+	Values    map[string]interface{} `json:"values"`
+}
+
+// ClusterArgs configures the AKS cluster, its agent pools, and the charts
+// deployed onto it.
+type ClusterArgs struct {
+	ResourceGroupName pulumi.StringInput
+	Location          pulumi.StringInput
+	Environment       string
+
+	// Agent pool sizing.
+	SystemNodeCount int
+	UserNodeMin     int
+	UserNodeMax     int
+	VmSize          string
+	EnableSpot      bool
+
+	// NetworkPlugin selects the AKS network plugin, e.g. "azure" or "kubenet".
+	NetworkPlugin string
+
+	// IdentityID, when set, is the ARM resource ID of a user-assigned
+	// managed identity used as the cluster's control-plane identity. When
+	// unset, the cluster falls back to a system-assigned identity.
+	IdentityID pulumi.StringInput
+
+	// Charts, deployed onto the cluster once it is ready.
+	Charts []ChartSpec
+
+	// KeyVault, when set, deploys a SecretProviderClass wiring the CSI
+	// secrets-store add-on to the given vault via the managed identity
+	// identified by ManagedIdentityClientId.
+	KeyVaultName            pulumi.StringInput
+	TenantId                pulumi.StringInput
+	ManagedIdentityClientId pulumi.StringInput
+
+	// Private networking, used when network.mode = "private" in stack
+	// config. SystemSubnetID/UserSubnetID place the agent pools inside the
+	// caller's VNet; PodCidr configures Azure CNI overlay pod networking.
+	// EnablePrivateCluster removes the cluster's public API server endpoint.
+	EnablePrivateCluster bool
+	SystemSubnetID       pulumi.StringInput
+	UserSubnetID         pulumi.StringInput
+	PodCidr              string
+}
+
+// Cluster is a ComponentResource bundling an AKS cluster together with the
+// Kubernetes provider and Helm releases that run on it.
+type Cluster struct {
+	pulumi.ResourceState
+
+	Name                 pulumi.StringOutput
+	Kubeconfig           pulumi.StringOutput
+	OidcIssuerUrl        pulumi.StringOutput
+	ChartReleaseStatuses pulumi.StringMapOutput
+
+	// KubeletObjectId is the object ID of the kubelet identity AKS
+	// provisions for the cluster, used to grant ACR pull / Key Vault
+	// access roles.
+	KubeletObjectId pulumi.StringOutput
+}
+
+// networkProfile builds the cluster's NetworkProfile, adding a pod CIDR for
+// Azure CNI overlay when args.PodCidr is set (the private-networking mode).
+func networkProfile(args *ClusterArgs) *containerservice.ContainerServiceNetworkProfileArgs {
+	profile := &containerservice.ContainerServiceNetworkProfileArgs{
+		NetworkPlugin: pulumi.String(args.NetworkPlugin),
+		ServiceCidr:   pulumi.String("10.0.0.0/16"),
+		DnsServiceIP:  pulumi.String("10.0.0.10"),
+	}
+	if args.PodCidr != "" {
+		profile.NetworkPluginMode = pulumi.String("overlay")
+		profile.PodCidr = pulumi.String(args.PodCidr)
+	}
+	return profile
+}
+
+// NewCluster provisions an AKS cluster plus the Helm charts configured in
+// args.Charts, exposing a typed kubeconfig and OIDC issuer URL for downstream
+// components (e.g. workload identity federation).
+func NewCluster(ctx *pulumi.Context, name string, args *ClusterArgs, opts ...pulumi.ResourceOption) (*Cluster, error) {
+	component := &Cluster{}
+	if err := ctx.RegisterComponentResource("aks:index:Cluster", name, component, opts...); err != nil {
+		return nil, err
+	}
+	parent := pulumi.Parent(component)
+
+	if args.SystemNodeCount == 0 {
+		args.SystemNodeCount = 2
+	}
+	if args.VmSize == "" {
+		args.VmSize = "Standard_D2s_v3"
+	}
+	if args.NetworkPlugin == "" {
+		args.NetworkPlugin = "azure"
+	}
+
+	systemPool := &containerservice.ManagedClusterAgentPoolProfileArgs{
+		Name:              pulumi.String("systempool"),
+		Count:             pulumi.Int(args.SystemNodeCount),
+		VmSize:            pulumi.String(args.VmSize),
+		OsDiskSizeGB:      pulumi.Int(30),
+		Mode:              pulumi.String("System"),
+		EnableAutoScaling: pulumi.Bool(true),
+		MinCount:          pulumi.Int(1),
+		MaxCount:          pulumi.Int(5),
+		Type:              pulumi.String("VirtualMachineScaleSets"),
+	}
+
+	userPool := &containerservice.ManagedClusterAgentPoolProfileArgs{
+		Name:              pulumi.String("userpool"),
+		Count:             pulumi.Int(2),
+		VmSize:            pulumi.String(args.VmSize),
+		OsDiskSizeGB:      pulumi.Int(30),
+		Mode:              pulumi.String("User"),
+		EnableAutoScaling: pulumi.Bool(true),
+		MinCount:          pulumi.Int(args.UserNodeMin),
+		MaxCount:          pulumi.Int(args.UserNodeMax),
+		Type:              pulumi.String("VirtualMachineScaleSets"),
+	}
+	if args.EnableSpot {
+		// Spot instances for cost optimization
+		userPool.ScaleSetPriority = pulumi.String("Spot")
+		userPool.SpotMaxPrice = pulumi.Float64(-1)
+	}
+	if args.SystemSubnetID != nil {
+		systemPool.VnetSubnetID = args.SystemSubnetID
+	}
+	if args.UserSubnetID != nil {
+		userPool.VnetSubnetID = args.UserSubnetID
+	}
+
+	identity := &containerservice.ManagedClusterIdentityArgs{
+		Type: containerservice.ResourceIdentityTypeSystemAssigned,
+	}
+	if args.IdentityID != nil {
+		identity.Type = containerservice.ResourceIdentityTypeUserAssigned
+		identity.UserAssignedIdentities = pulumi.StringArray{args.IdentityID}
+	}
+
+	cluster, err := containerservice.NewManagedCluster(ctx, name, &containerservice.ManagedClusterArgs{
+		ResourceGroupName: args.ResourceGroupName,
+		ResourceName:      pulumi.Sprintf("aks-%s", args.Environment),
+		Location:          args.Location,
+
+		KubernetesVersion: pulumi.String("1.28.0"),
+		DnsPrefix:         pulumi.Sprintf("aks-%s", args.Environment),
+
+		// Azure AD RBAC for Kubernetes authorization, required by the
+		// aks-rbac-enabled CrossGuard policy.
+		EnableRBAC: pulumi.Bool(true),
+
+		Identity: identity,
+
+		// OIDC issuer, so downstream components can wire up workload
+		// identity federation without a second deploy.
+		OidcIssuerProfile: &containerservice.ManagedClusterOIDCIssuerProfileArgs{
+			Enabled: pulumi.Bool(true),
+		},
+
+		AgentPoolProfiles: containerservice.ManagedClusterAgentPoolProfileArray{
+			systemPool,
+			userPool,
+		},
+
+		NetworkProfile: networkProfile(args),
+
+		ApiServerAccessProfile: &containerservice.ManagedClusterAPIServerAccessProfileArgs{
+			EnablePrivateCluster: pulumi.Bool(args.EnablePrivateCluster),
+		},
+
+		AddonProfiles: containerservice.ManagedClusterAddonProfileMap{
+			"azureKeyvaultSecretsProvider": &containerservice.ManagedClusterAddonProfileArgs{
+				Enabled: pulumi.Bool(true),
+			},
+			"omsagent": &containerservice.ManagedClusterAddonProfileArgs{
+				Enabled: pulumi.Bool(true),
+			},
+		},
+
+		Tags: pulumi.StringMap{
+			"Environment": pulumi.String(args.Environment),
+			"ManagedBy":   pulumi.String("Pulumi"),
+		},
+	}, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kubeconfig, resolved from the cluster's user credentials so the
+	// Kubernetes provider below can target it directly instead of shelling
+	// out to `az aks get-credentials`.
+	userCreds := containerservice.ListManagedClusterUserCredentialsOutput(ctx, containerservice.ListManagedClusterUserCredentialsOutputArgs{
+		ResourceGroupName: args.ResourceGroupName,
+		ResourceName:      cluster.Name,
+	})
+	kubeconfig := userCreds.Kubeconfigs().Index(pulumi.Int(0)).Value().ApplyT(
+		func(encoded string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return "", err
+			}
+			return string(decoded), nil
+		},
+	).(pulumi.StringOutput)
+
+	k8sProvider, err := kubernetes.NewProvider(ctx, name+"-k8s", &kubernetes.ProviderArgs{
+		Kubeconfig: kubeconfig,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{cluster}))
+	if err != nil {
+		return nil, err
+	}
+
+	releaseStatuses := pulumi.StringMap{}
+	for _, chart := range args.Charts {
+		values := pulumi.Map{}
+		for k, v := range chart.Values {
+			values[k] = pulumi.Any(v)
+		}
+
+		release, err := helmv3.NewRelease(ctx, chart.Name, &helmv3.ReleaseArgs{
+			Name:      pulumi.String(chart.Name),
+			Chart:     pulumi.String(chart.Chart),
+			Version:   pulumi.String(chart.Version),
+			Namespace: pulumi.String(chart.Namespace),
+			RepositoryOpts: helmv3.RepositoryOptsArgs{
+				Repo: pulumi.String(chart.Repo),
+			},
+			Values:          values,
+			CreateNamespace: pulumi.Bool(true),
+		}, pulumi.Provider(k8sProvider), parent)
+		if err != nil {
+			return nil, err
+		}
+
+		releaseStatuses[chart.Name] = release.Status.Status()
+	}
+
+	// Wire the CSI secrets-store add-on (enabled via AddonProfiles above) to
+	// an example SecretProviderClass, so pods can mount KeyVault secrets via
+	// the cluster's managed identity without any extra cluster-side config.
+	if args.KeyVaultName != nil {
+		_, err := apiextensions.NewCustomResource(ctx, name+"-secret-provider", &apiextensions.CustomResourceArgs{
+			ApiVersion: pulumi.String("secrets-store.csi.x-k8s.io/v1"),
+			Kind:       pulumi.String("SecretProviderClass"),
+			Metadata: &metav1.ObjectMetaArgs{
+				Name:      pulumi.String("azure-keyvault-secrets"),
+				Namespace: pulumi.String("default"),
+			},
+			OtherFields: kubernetes.UntypedArgs{
+				"spec": pulumi.Map{
+					"provider": pulumi.String("azure"),
+					"parameters": pulumi.Map{
+						"usePodIdentity":         pulumi.String("false"),
+						"useVMManagedIdentity":   pulumi.String("true"),
+						"userAssignedIdentityID": args.ManagedIdentityClientId,
+						"keyvaultName":           args.KeyVaultName,
+						"tenantId":               args.TenantId,
+						"objects": pulumi.String(`array:
+  - |
+    objectName: example-secret
+    objectType: secret`),
+					},
+				},
+			},
+		}, pulumi.Provider(k8sProvider), parent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	component.Name = cluster.Name
+	component.KubeletObjectId = cluster.IdentityProfile.MapIndex(pulumi.String("kubeletidentity")).ObjectId().Elem()
+	// The kubeconfig carries a client certificate and key, so it is marked
+	// secret to keep it out of plaintext state and `pulumi stack output`.
+	component.Kubeconfig = pulumi.ToSecret(kubeconfig).(pulumi.StringOutput)
+	component.OidcIssuerUrl = cluster.OidcIssuerProfile.IssuerURL().Elem()
+	component.ChartReleaseStatuses = releaseStatuses.ToStringMapOutput()
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"name":                 component.Name,
+		"kubeconfig":           component.Kubeconfig,
+		"oidcIssuerUrl":        component.OidcIssuerUrl,
+		"chartReleaseStatuses": component.ChartReleaseStatuses,
+		"kubeletObjectId":      component.KubeletObjectId,
+	}); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
@@ -0,0 +1,118 @@
+// Package data provides a reusable Pulumi ComponentResource wrapping an
+// Azure Cosmos DB account.
+package data
+
+import (
+	"github.com/pulumi/pulumi-azure-native-sdk/documentdb/v2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// CosmosAccountArgs configures the Cosmos DB account and its geo-replica
+// locations.
+type CosmosAccountArgs struct {
+	ResourceGroupName pulumi.StringInput
+	Location          pulumi.StringInput
+	Environment       string
+
+	// GeoReplicaLocations, in failover priority order, added after the
+	// primary Location. May be empty for a single-region account.
+	GeoReplicaLocations []string
+
+	// DisablePublicNetworkAccess turns off the account's public endpoint,
+	// used in network.mode = "private" alongside a private endpoint.
+	DisablePublicNetworkAccess bool
+}
+
+// CosmosAccount is a ComponentResource wrapping a Cosmos DB account
+// configured with continuous backup and automatic failover.
+type CosmosAccount struct {
+	pulumi.ResourceState
+
+	Id   pulumi.StringOutput
+	Name pulumi.StringOutput
+
+	// PrimaryKey and PrimaryConnectionString are secrets: they are
+	// retrieved via ListDatabaseAccountKeysOutput and wrapped with
+	// pulumi.ToSecret so they never land in state or CLI output in
+	// plaintext.
+	PrimaryKey              pulumi.StringOutput
+	PrimaryConnectionString pulumi.StringOutput
+}
+
+// NewCosmosAccount provisions a Cosmos DB account in the given resource
+// group.
+func NewCosmosAccount(ctx *pulumi.Context, name string, args *CosmosAccountArgs, opts ...pulumi.ResourceOption) (*CosmosAccount, error) {
+	component := &CosmosAccount{}
+	if err := ctx.RegisterComponentResource("data:index:CosmosAccount", name, component, opts...); err != nil {
+		return nil, err
+	}
+
+	locations := documentdb.LocationArray{
+		&documentdb.LocationArgs{
+			LocationName:     args.Location,
+			FailoverPriority: pulumi.Int(0),
+		},
+	}
+	for i, replica := range args.GeoReplicaLocations {
+		locations = append(locations, &documentdb.LocationArgs{
+			LocationName:     pulumi.String(replica),
+			FailoverPriority: pulumi.Int(i + 1),
+		})
+	}
+
+	publicNetworkAccess := documentdb.PublicNetworkAccessEnabled
+	if args.DisablePublicNetworkAccess {
+		publicNetworkAccess = documentdb.PublicNetworkAccessDisabled
+	}
+
+	account, err := documentdb.NewDatabaseAccount(ctx, name, &documentdb.DatabaseAccountArgs{
+		ResourceGroupName: args.ResourceGroupName,
+		AccountName:       pulumi.Sprintf("cosmos-%s", args.Environment),
+		Location:          args.Location,
+
+		DatabaseAccountOfferType: documentdb.DatabaseAccountOfferTypeStandard,
+		PublicNetworkAccess:      pulumi.StringPtr(string(publicNetworkAccess)),
+
+		Locations: locations,
+
+		ConsistencyPolicy: &documentdb.ConsistencyPolicyArgs{
+			DefaultConsistencyLevel: documentdb.DefaultConsistencyLevelSession,
+		},
+
+		// Enable automatic failover
+		EnableAutomaticFailover: pulumi.Bool(true),
+
+		// Backup policy
+		BackupPolicy: pulumi.Map{
+			"type": pulumi.String("Continuous"),
+		},
+
+		Tags: pulumi.StringMap{
+			"Environment": pulumi.String(args.Environment),
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := documentdb.ListDatabaseAccountKeysOutput(ctx, documentdb.ListDatabaseAccountKeysOutputArgs{
+		ResourceGroupName: args.ResourceGroupName,
+		AccountName:       account.Name,
+	})
+	primaryKey := keys.PrimaryMasterKey()
+	connectionString := pulumi.Sprintf("AccountEndpoint=%s;AccountKey=%s;", account.DocumentEndpoint, primaryKey)
+
+	component.Id = account.ID().ToIDOutput().ApplyT(func(id pulumi.ID) string { return string(id) }).(pulumi.StringOutput)
+	component.Name = account.Name
+	component.PrimaryKey = pulumi.ToSecret(primaryKey).(pulumi.StringOutput)
+	component.PrimaryConnectionString = pulumi.ToSecret(connectionString).(pulumi.StringOutput)
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"id":   component.Id,
+		"name": component.Name,
+	}); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
@@ -0,0 +1,88 @@
+// Package identity provides the Azure AD plumbing shared across components:
+// a user-assigned managed identity for the AKS cluster, and a helper for
+// granting that identity (or any other principal) a built-in RBAC role on a
+// resource scope.
+package identity
+
+import (
+	"github.com/pulumi/pulumi-azure-native-sdk/authorization/v2"
+	"github.com/pulumi/pulumi-azure-native-sdk/managedidentity/v2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Well-known built-in role definition GUIDs, documented at
+// https://learn.microsoft.com/azure/role-based-access-control/built-in-roles.
+const (
+	RoleAcrPull                = "7f951dda-4ed3-4680-a7ca-43fe172d538d"
+	RoleKeyVaultSecretsUser    = "4633458b-17de-408a-b874-0445c86b69e6"
+	RoleKeyVaultSecretsOfficer = "b86a8fe4-44ce-4948-aee5-eccb2c155cd7"
+)
+
+// ManagedIdentityArgs configures the user-assigned identity.
+type ManagedIdentityArgs struct {
+	ResourceGroupName pulumi.StringInput
+	Location          pulumi.StringInput
+	Environment       string
+}
+
+// ManagedIdentity is a ComponentResource wrapping a user-assigned managed
+// identity, used as the AKS cluster's control-plane identity so that role
+// assignments (ACR pull, Key Vault access, ...) can be granted before the
+// cluster exists.
+type ManagedIdentity struct {
+	pulumi.ResourceState
+
+	Id          pulumi.StringOutput
+	ClientId    pulumi.StringOutput
+	PrincipalId pulumi.StringOutput
+}
+
+// NewManagedIdentity provisions a user-assigned managed identity.
+func NewManagedIdentity(ctx *pulumi.Context, name string, args *ManagedIdentityArgs, opts ...pulumi.ResourceOption) (*ManagedIdentity, error) {
+	component := &ManagedIdentity{}
+	if err := ctx.RegisterComponentResource("identity:index:ManagedIdentity", name, component, opts...); err != nil {
+		return nil, err
+	}
+
+	mi, err := managedidentity.NewUserAssignedIdentity(ctx, name, &managedidentity.UserAssignedIdentityArgs{
+		ResourceGroupName: args.ResourceGroupName,
+		ResourceName:      pulumi.Sprintf("id-%s", args.Environment),
+		Location:          args.Location,
+		Tags: pulumi.StringMap{
+			"Environment": pulumi.String(args.Environment),
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return nil, err
+	}
+
+	component.Id = mi.ID().ToIDOutput().ApplyT(func(id pulumi.ID) string { return string(id) }).(pulumi.StringOutput)
+	component.ClientId = mi.ClientId
+	component.PrincipalId = mi.PrincipalId
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"id":          component.Id,
+		"clientId":    component.ClientId,
+		"principalId": component.PrincipalId,
+	}); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// GrantRole assigns a built-in role (one of the Role* constants, or any
+// role definition GUID) to principalId on the given scope. subscriptionId
+// is the subscription the built-in role definition is resolved from (built-in
+// roles are defined per-subscription, not globally), typically
+// clientConfig.SubscriptionId from authorization.GetClientConfig.
+func GrantRole(ctx *pulumi.Context, name string, scope pulumi.StringInput, roleDefinitionId string, principalId pulumi.StringInput, subscriptionId pulumi.StringInput, opts ...pulumi.ResourceOption) (*authorization.RoleAssignment, error) {
+	return authorization.NewRoleAssignment(ctx, name, &authorization.RoleAssignmentArgs{
+		Scope: scope,
+		RoleDefinitionId: pulumi.Sprintf(
+			"/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s", subscriptionId, roleDefinitionId,
+		),
+		PrincipalId:   principalId,
+		PrincipalType: pulumi.StringPtr(string(authorization.PrincipalTypeServicePrincipal)),
+	}, opts...)
+}
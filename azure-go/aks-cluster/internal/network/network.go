@@ -0,0 +1,176 @@
+// Package network provides the VNet-integrated, private-cluster networking
+// mode: a virtual network with dedicated subnets for the AKS node pools and
+// pod CIDR, plus private endpoints (with linked Private DNS zones) for
+// dependencies such as Key Vault and Cosmos DB.
+package network
+
+import (
+	"github.com/pulumi/pulumi-azure-native-sdk/network/v2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// VirtualNetworkArgs configures the VNet and its subnets.
+type VirtualNetworkArgs struct {
+	ResourceGroupName pulumi.StringInput
+	Location          pulumi.StringInput
+	Environment       string
+
+	// AddressSpace is the VNet's CIDR, e.g. "10.10.0.0/16".
+	AddressSpace string
+
+	// SystemSubnetCidr, UserSubnetCidr, and PrivateEndpointSubnetCidr carve
+	// up AddressSpace for the system pool, user pool, and private
+	// endpoints respectively. PodCidr is routed separately (Azure CNI
+	// overlay) and is not a VNet subnet.
+	SystemSubnetCidr          string
+	UserSubnetCidr            string
+	PrivateEndpointSubnetCidr string
+}
+
+// VirtualNetwork is a ComponentResource wrapping a VNet with the subnets AKS
+// and its private endpoints need.
+type VirtualNetwork struct {
+	pulumi.ResourceState
+
+	Id                      pulumi.StringOutput
+	SystemSubnetId          pulumi.StringOutput
+	UserSubnetId            pulumi.StringOutput
+	PrivateEndpointSubnetId pulumi.StringOutput
+}
+
+// NewVirtualNetwork provisions a VNet and its subnets.
+func NewVirtualNetwork(ctx *pulumi.Context, name string, args *VirtualNetworkArgs, opts ...pulumi.ResourceOption) (*VirtualNetwork, error) {
+	component := &VirtualNetwork{}
+	if err := ctx.RegisterComponentResource("network:index:VirtualNetwork", name, component, opts...); err != nil {
+		return nil, err
+	}
+	parent := pulumi.Parent(component)
+
+	vnet, err := network.NewVirtualNetwork(ctx, name, &network.VirtualNetworkArgs{
+		ResourceGroupName:  args.ResourceGroupName,
+		VirtualNetworkName: pulumi.Sprintf("vnet-%s", args.Environment),
+		Location:           args.Location,
+		AddressSpace: &network.AddressSpaceArgs{
+			AddressPrefixes: pulumi.StringArray{pulumi.String(args.AddressSpace)},
+		},
+		Subnets: network.SubnetTypeArray{
+			&network.SubnetTypeArgs{
+				Name:          pulumi.String("systempool"),
+				AddressPrefix: pulumi.String(args.SystemSubnetCidr),
+			},
+			&network.SubnetTypeArgs{
+				Name:          pulumi.String("userpool"),
+				AddressPrefix: pulumi.String(args.UserSubnetCidr),
+			},
+			&network.SubnetTypeArgs{
+				Name:                           pulumi.String("privateendpoints"),
+				AddressPrefix:                  pulumi.String(args.PrivateEndpointSubnetCidr),
+				PrivateEndpointNetworkPolicies: pulumi.String("Disabled"),
+			},
+		},
+		Tags: pulumi.StringMap{
+			"Environment": pulumi.String(args.Environment),
+		},
+	}, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	component.Id = vnet.ID().ToIDOutput().ApplyT(func(id pulumi.ID) string { return string(id) }).(pulumi.StringOutput)
+	component.SystemSubnetId = pulumi.Sprintf("%s/subnets/systempool", component.Id)
+	component.UserSubnetId = pulumi.Sprintf("%s/subnets/userpool", component.Id)
+	component.PrivateEndpointSubnetId = pulumi.Sprintf("%s/subnets/privateendpoints", component.Id)
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"id":                      component.Id,
+		"systemSubnetId":          component.SystemSubnetId,
+		"userSubnetId":            component.UserSubnetId,
+		"privateEndpointSubnetId": component.PrivateEndpointSubnetId,
+	}); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// PrivateEndpointArgs configures a private endpoint and its linked Private
+// DNS zone for a single dependency resource (e.g. a Key Vault or Cosmos DB
+// account).
+type PrivateEndpointArgs struct {
+	ResourceGroupName pulumi.StringInput
+	Location          pulumi.StringInput
+	VnetId            pulumi.StringInput
+	SubnetId          pulumi.StringInput
+
+	// TargetResourceId is the ARM resource ID of the dependency the private
+	// endpoint connects to.
+	TargetResourceId pulumi.StringInput
+	// GroupId selects the sub-resource the endpoint connects to, e.g.
+	// "vault" or "Sql" (Cosmos DB's SQL API endpoint group).
+	GroupId string
+	// PrivateDnsZoneName is the well-known zone for the service, e.g.
+	// "privatelink.vaultcore.azure.net".
+	PrivateDnsZoneName string
+}
+
+// NewPrivateEndpoint provisions a private endpoint for TargetResourceId,
+// together with a Private DNS zone linked to VnetId and auto-registered via
+// a DNS zone group, so the endpoint resolves from inside the VNet with no
+// extra client-side configuration.
+func NewPrivateEndpoint(ctx *pulumi.Context, name string, args *PrivateEndpointArgs, opts ...pulumi.ResourceOption) error {
+	zone, err := network.NewPrivateZone(ctx, name+"-zone", &network.PrivateZoneArgs{
+		ResourceGroupName: args.ResourceGroupName,
+		PrivateZoneName:   pulumi.String(args.PrivateDnsZoneName),
+		Location:          pulumi.String("global"),
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := network.NewVirtualNetworkLink(ctx, name+"-link", &network.VirtualNetworkLinkArgs{
+		ResourceGroupName:   args.ResourceGroupName,
+		PrivateZoneName:     zone.Name,
+		Location:            pulumi.String("global"),
+		RegistrationEnabled: pulumi.Bool(false),
+		VirtualNetwork: &network.SubResourceArgs{
+			Id: args.VnetId,
+		},
+	}, opts...); err != nil {
+		return err
+	}
+
+	endpoint, err := network.NewPrivateEndpoint(ctx, name, &network.PrivateEndpointArgs{
+		ResourceGroupName: args.ResourceGroupName,
+		Location:          args.Location,
+		Subnet: &network.SubnetTypeArgs{
+			Id: args.SubnetId,
+		},
+		PrivateLinkServiceConnections: network.PrivateLinkServiceConnectionArray{
+			&network.PrivateLinkServiceConnectionArgs{
+				Name:                 pulumi.String(name),
+				PrivateLinkServiceId: args.TargetResourceId,
+				GroupIds:             pulumi.StringArray{pulumi.String(args.GroupId)},
+			},
+		},
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := network.NewPrivateDnsZoneGroup(ctx, name+"-dns-group", &network.PrivateDnsZoneGroupArgs{
+		ResourceGroupName:   args.ResourceGroupName,
+		PrivateEndpointName: endpoint.Name,
+		PrivateDnsZoneConfigs: network.PrivateDnsZoneConfigArray{
+			&network.PrivateDnsZoneConfigArgs{
+				Name: pulumi.String(args.PrivateDnsZoneName),
+				PrivateDnsZoneId: zone.ID().ToIDOutput().ApplyT(
+					func(id pulumi.ID) string { return string(id) },
+				).(pulumi.StringOutput),
+			},
+		},
+	}, opts...); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -4,9 +4,13 @@
 package main
 
 import (
-	"github.com/pulumi/pulumi-azure-native-sdk/containerservice/v2"
-	"github.com/pulumi/pulumi-azure-native-sdk/documentdb/v2"
-	"github.com/pulumi/pulumi-azure-native-sdk/keyvault/v2"
+	"aks-cluster/internal/aks"
+	"aks-cluster/internal/data"
+	"aks-cluster/internal/identity"
+	"aks-cluster/internal/network"
+	"aks-cluster/internal/security"
+
+	"github.com/pulumi/pulumi-azure-native-sdk/authorization/v2"
 	"github.com/pulumi/pulumi-azure-native-sdk/resources/v2"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
@@ -19,8 +23,24 @@ func main() {
 		if location == "" {
 			location = "eastus"
 		}
+		// acrId, when set, grants the cluster's kubelet identity AcrPull on
+		// that registry so nodes can pull private images.
+		acrId := cfg.Get("acrId")
+		// networkMode gates the VNet-integrated / private-cluster mode: the
+		// simple public mode (default) leaves AKS and its dependencies on
+		// their public endpoints, exactly as before.
+		networkMode := cfg.Get("network.mode")
+		if networkMode == "" {
+			networkMode = "public"
+		}
+		private := networkMode == "private"
 		environment := ctx.Stack()
 
+		clientConfig, err := authorization.GetClientConfig(ctx, nil)
+		if err != nil {
+			return err
+		}
+
 		// Resource Group
 		resourceGroup, err := resources.NewResourceGroup(ctx, "rg", &resources.ResourceGroupArgs{
 			ResourceGroupName: pulumi.Sprintf("rg-aks-%s", environment),
@@ -34,151 +54,146 @@ func main() {
 			return err
 		}
 
-		// AKS Cluster
-		cluster, err := containerservice.NewManagedCluster(ctx, "aksCluster", &containerservice.ManagedClusterArgs{
+		// User-assigned identity for the AKS cluster, so role assignments
+		// (ACR pull, Key Vault access) can be granted independently of the
+		// cluster's own lifecycle.
+		aksIdentity, err := identity.NewManagedIdentity(ctx, "aksIdentity", &identity.ManagedIdentityArgs{
 			ResourceGroupName: resourceGroup.Name,
-			ResourceName:      pulumi.Sprintf("aks-%s", environment),
 			Location:          resourceGroup.Location,
-			
-			KubernetesVersion: pulumi.String("1.28.0"),
-			DnsPrefix:         pulumi.Sprintf("aks-%s", environment),
-			
-			Identity: &containerservice.ManagedClusterIdentityArgs{
-				Type: containerservice.ResourceIdentityTypeSystemAssigned,
-			},
-			
-			// Agent Pools
-			AgentPoolProfiles: containerservice.ManagedClusterAgentPoolProfileArray{
-				&containerservice.ManagedClusterAgentPoolProfileArgs{
-					Name:              pulumi.String("systempool"),
-					Count:             pulumi.Int(2),
-					VmSize:            pulumi.String("Standard_D2s_v3"),
-					OsDiskSizeGB:      pulumi.Int(30),
-					Mode:              pulumi.String("System"),
-					EnableAutoScaling: pulumi.Bool(true),
-					MinCount:          pulumi.Int(1),
-					MaxCount:          pulumi.Int(5),
-					Type:              pulumi.String("VirtualMachineScaleSets"),
-				},
-				&containerservice.ManagedClusterAgentPoolProfileArgs{
-					Name:              pulumi.String("userpool"),
-					Count:             pulumi.Int(2),
-					VmSize:            pulumi.String("Standard_D2s_v3"),
-					OsDiskSizeGB:      pulumi.Int(30),
-					Mode:              pulumi.String("User"),
-					EnableAutoScaling: pulumi.Bool(true),
-					MinCount:          pulumi.Int(0),
-					MaxCount:          pulumi.Int(10),
-					// Spot instances for cost optimization
-					ScaleSetPriority: pulumi.String("Spot"),
-					SpotMaxPrice:     pulumi.Float64(-1),
-					Type:             pulumi.String("VirtualMachineScaleSets"),
-				},
-			},
-			
-			// Network Configuration
-			NetworkProfile: &containerservice.ContainerServiceNetworkProfileArgs{
-				NetworkPlugin: pulumi.String("azure"),
-				ServiceCidr:   pulumi.String("10.0.0.0/16"),
-				DnsServiceIP:  pulumi.String("10.0.0.10"),
-			},
-			
-			// Add-ons
-			AddonProfiles: containerservice.ManagedClusterAddonProfileMap{
-				"azureKeyvaultSecretsProvider": &containerservice.ManagedClusterAddonProfileArgs{
-					Enabled: pulumi.Bool(true),
-				},
-				"omsagent": &containerservice.ManagedClusterAddonProfileArgs{
-					Enabled: pulumi.Bool(true),
-				},
-			},
-			
-			Tags: pulumi.StringMap{
-				"Environment": pulumi.String(environment),
-				"ManagedBy":   pulumi.String("Pulumi"),
-			},
+			Environment:       environment,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Azure Key Vault
-		vault, err := keyvault.NewVault(ctx, "keyVault", &keyvault.VaultArgs{
-			ResourceGroupName: resourceGroup.Name,
-			VaultName:         pulumi.Sprintf("kv-%s", environment),
-			Location:          resourceGroup.Location,
-			
-			Properties: &keyvault.VaultPropertiesArgs{
-				TenantId: pulumi.String("TENANT_ID"), // Replace with actual tenant ID
-				Sku: &keyvault.SkuArgs{
-					Family: pulumi.String("A"),
-					Name:   keyvault.SkuNameStandard,
-				},
-				EnabledForDeployment:         pulumi.Bool(true),
-				EnabledForDiskEncryption:     pulumi.Bool(true),
-				EnabledForTemplateDeployment: pulumi.Bool(true),
-				EnableSoftDelete:             pulumi.Bool(true),
-				SoftDeleteRetentionInDays:    pulumi.Int(90),
-				EnablePurgeProtection:        pulumi.Bool(true),
-			},
-			
-			Tags: pulumi.StringMap{
-				"Environment": pulumi.String(environment),
-			},
+		// VNet, subnets, and private endpoints, only provisioned in private
+		// networking mode. vnet is nil in public mode.
+		var vnet *network.VirtualNetwork
+		if private {
+			vnet, err = network.NewVirtualNetwork(ctx, "vnet", &network.VirtualNetworkArgs{
+				ResourceGroupName:         resourceGroup.Name,
+				Location:                  resourceGroup.Location,
+				Environment:               environment,
+				AddressSpace:              "10.10.0.0/16",
+				SystemSubnetCidr:          "10.10.1.0/24",
+				UserSubnetCidr:            "10.10.2.0/24",
+				PrivateEndpointSubnetCidr: "10.10.3.0/24",
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		vault, err := security.NewVault(ctx, "keyVault", &security.VaultArgs{
+			ResourceGroupName:          resourceGroup.Name,
+			Location:                   resourceGroup.Location,
+			Environment:                environment,
+			TenantId:                   pulumi.String(clientConfig.TenantId),
+			DisablePublicNetworkAccess: private,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Cosmos DB Account
-		cosmosAccount, err := documentdb.NewDatabaseAccount(ctx, "cosmosAccount", &documentdb.DatabaseAccountArgs{
-			ResourceGroupName: resourceGroup.Name,
-			AccountName:       pulumi.Sprintf("cosmos-%s", environment),
-			Location:          resourceGroup.Location,
-			
-			DatabaseAccountOfferType: pulumi.String("Standard"),
-			
-			Locations: documentdb.LocationArray{
-				&documentdb.LocationArgs{
-					LocationName:     resourceGroup.Location,
-					FailoverPriority: pulumi.Int(0),
-				},
-			},
-			
-			ConsistencyPolicy: &documentdb.ConsistencyPolicyArgs{
-				DefaultConsistencyLevel: documentdb.DefaultConsistencyLevelSession,
-			},
-			
-			// Enable automatic failover
-			EnableAutomaticFailover: pulumi.Bool(true),
-			
-			// Backup policy
-			BackupPolicy: &documentdb.ContinuousModeBackupPolicyArgs{
-				Type: pulumi.String("Continuous"),
-			},
-			
-			Tags: pulumi.StringMap{
-				"Environment": pulumi.String(environment),
-			},
+		// The vault is RBAC-authorized, so the deploying principal needs an
+		// explicit role grant to manage secrets - a vault-local access
+		// policy would be silently ignored.
+		if _, err := identity.GrantRole(ctx, "operatorVaultSecretsOfficer", vault.Id, identity.RoleKeyVaultSecretsOfficer, pulumi.String(clientConfig.ObjectId), pulumi.String(clientConfig.SubscriptionId)); err != nil {
+			return err
+		}
+
+		var charts []aks.ChartSpec
+		cfg.TryObject("charts", &charts)
+
+		clusterArgs := &aks.ClusterArgs{
+			ResourceGroupName:       resourceGroup.Name,
+			Location:                resourceGroup.Location,
+			Environment:             environment,
+			UserNodeMin:             0,
+			UserNodeMax:             10,
+			EnableSpot:              true,
+			IdentityID:              aksIdentity.Id,
+			Charts:                  charts,
+			KeyVaultName:            vault.Name,
+			TenantId:                pulumi.String(clientConfig.TenantId),
+			ManagedIdentityClientId: aksIdentity.ClientId,
+		}
+		if private {
+			// EnablePrivateCluster drops the API server onto a private FQDN
+			// that only resolves inside vnet (or a peered/VPN-connected
+			// network). That also applies to the Helm releases aks.NewCluster
+			// installs via the Kubernetes provider below: `pulumi up` itself
+			// must run from somewhere with network access to the private
+			// endpoint, e.g. a self-hosted deployment agent inside the VNet,
+			// not an unpeered CI runner or a developer's laptop.
+			clusterArgs.EnablePrivateCluster = true
+			clusterArgs.PodCidr = "10.244.0.0/16"
+			clusterArgs.SystemSubnetID = vnet.SystemSubnetId
+			clusterArgs.UserSubnetID = vnet.UserSubnetId
+		}
+
+		cluster, err := aks.NewCluster(ctx, "aksCluster", clusterArgs)
+		if err != nil {
+			return err
+		}
+
+		// Grant the cluster's kubelet identity access to the secrets it
+		// needs at runtime: KeyVault secrets via the CSI add-on, and ACR
+		// pull when a registry is configured.
+		if _, err := identity.GrantRole(ctx, "aksVaultSecretsUser", vault.Id, identity.RoleKeyVaultSecretsUser, cluster.KubeletObjectId, pulumi.String(clientConfig.SubscriptionId)); err != nil {
+			return err
+		}
+		if acrId != "" {
+			if _, err := identity.GrantRole(ctx, "aksAcrPull", pulumi.String(acrId), identity.RoleAcrPull, cluster.KubeletObjectId, pulumi.String(clientConfig.SubscriptionId)); err != nil {
+				return err
+			}
+		}
+
+		cosmosAccount, err := data.NewCosmosAccount(ctx, "cosmosAccount", &data.CosmosAccountArgs{
+			ResourceGroupName:          resourceGroup.Name,
+			Location:                   resourceGroup.Location,
+			Environment:                environment,
+			DisablePublicNetworkAccess: private,
 		})
 		if err != nil {
 			return err
 		}
 
+		if private {
+			if err := network.NewPrivateEndpoint(ctx, "keyVaultPe", &network.PrivateEndpointArgs{
+				ResourceGroupName:  resourceGroup.Name,
+				Location:           resourceGroup.Location,
+				VnetId:             vnet.Id,
+				SubnetId:           vnet.PrivateEndpointSubnetId,
+				TargetResourceId:   vault.Id,
+				GroupId:            "vault",
+				PrivateDnsZoneName: "privatelink.vaultcore.azure.net",
+			}); err != nil {
+				return err
+			}
+			if err := network.NewPrivateEndpoint(ctx, "cosmosPe", &network.PrivateEndpointArgs{
+				ResourceGroupName:  resourceGroup.Name,
+				Location:           resourceGroup.Location,
+				VnetId:             vnet.Id,
+				SubnetId:           vnet.PrivateEndpointSubnetId,
+				TargetResourceId:   cosmosAccount.Id,
+				GroupId:            "Sql",
+				PrivateDnsZoneName: "privatelink.documents.azure.com",
+			}); err != nil {
+				return err
+			}
+		}
+
 		// Exports
 		ctx.Export("resourceGroupName", resourceGroup.Name)
 		ctx.Export("aksClusterName", cluster.Name)
 		ctx.Export("keyVaultName", vault.Name)
 		ctx.Export("cosmosAccountName", cosmosAccount.Name)
-		
-		// Kubeconfig
-		ctx.Export("kubeconfig", pulumi.All(cluster.Name, resourceGroup.Name).ApplyT(
-			func(args []interface{}) (string, error) {
-				clusterName := args[0].(string)
-				rgName := args[1].(string)
-				return pulumi.Sprintf("az aks get-credentials --resource-group %s --name %s", rgName, clusterName).StringValue(), nil
-			},
-		))
+		ctx.Export("chartReleaseStatuses", cluster.ChartReleaseStatuses)
+		ctx.Export("oidcIssuerUrl", cluster.OidcIssuerUrl)
+		ctx.Export("kubeconfig", cluster.Kubeconfig)
+		ctx.Export("cosmosPrimaryKey", cosmosAccount.PrimaryKey)
+		ctx.Export("cosmosPrimaryConnectionString", cosmosAccount.PrimaryConnectionString)
 
 		return nil
 	})
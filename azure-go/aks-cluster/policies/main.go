@@ -0,0 +1,153 @@
+// CrossGuard policy pack enforcing compliance guardrails on the resources
+// declared in ../main.go. Run with:
+//
+//	pulumi preview --policy-pack ./policies
+package main
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/policy"
+)
+
+const minKubernetesVersion = "1.27.0"
+const minSoftDeleteRetentionDays = 30
+
+func main() {
+	policy.NewPolicyPack("azure-baseline", policy.PolicyPackArgs{
+		Policies: []policy.Policy{
+			aksRbacEnabled(),
+			aksKubernetesVersionFloor(),
+			aksAutoscalingRequiredInProd(),
+			vaultSoftDeleteAndPurgeProtection(),
+			cosmosBackupAndFailover(),
+		},
+	}).Run()
+}
+
+func aksRbacEnabled() *policy.ResourceValidationPolicy {
+	return &policy.ResourceValidationPolicy{
+		Name:             "aks-rbac-enabled",
+		Description:      "AKS clusters must have Azure AD RBAC enabled.",
+		EnforcementLevel: policy.Mandatory,
+		ValidateResource: policy.ResourceValidation(func(args *policy.ResourceValidationArgs, manager *policy.ValidationManager) error {
+			if args.Resource.Type != "azure-native:containerservice:ManagedCluster" {
+				return nil
+			}
+			if enabled, ok := args.Resource.Properties["enableRBAC"].BoolValueOk(); !ok || !enabled {
+				manager.ReportViolation("AKS cluster must set enableRBAC: true", "")
+			}
+			return nil
+		}),
+	}
+}
+
+func aksKubernetesVersionFloor() *policy.ResourceValidationPolicy {
+	return &policy.ResourceValidationPolicy{
+		Name:             "aks-kubernetes-version-floor",
+		Description:      fmt.Sprintf("AKS clusters must run a Kubernetes version newer than %s.", minKubernetesVersion),
+		EnforcementLevel: policy.Mandatory,
+		ValidateResource: policy.ResourceValidation(func(args *policy.ResourceValidationArgs, manager *policy.ValidationManager) error {
+			if args.Resource.Type != "azure-native:containerservice:ManagedCluster" {
+				return nil
+			}
+			version, ok := args.Resource.Properties["kubernetesVersion"].StringValueOk()
+			if !ok {
+				manager.ReportViolation(
+					fmt.Sprintf("kubernetesVersion must be newer than the %s floor", minKubernetesVersion), "")
+				return nil
+			}
+			parsed, err := semver.ParseTolerant(version)
+			if err != nil || parsed.LT(semver.MustParse(minKubernetesVersion)) {
+				manager.ReportViolation(
+					fmt.Sprintf("kubernetesVersion must be newer than the %s floor", minKubernetesVersion), "")
+			}
+			return nil
+		}),
+	}
+}
+
+func aksAutoscalingRequiredInProd() *policy.ResourceValidationPolicy {
+	return &policy.ResourceValidationPolicy{
+		Name:             "aks-autoscaling-required-in-prod",
+		Description:      "In the prod stack, every agent pool must have autoscaling enabled.",
+		EnforcementLevel: policy.Mandatory,
+		ValidateResource: policy.ResourceValidation(func(args *policy.ResourceValidationArgs, manager *policy.ValidationManager) error {
+			if args.Resource.Type != "azure-native:containerservice:ManagedCluster" {
+				return nil
+			}
+			if manager.Stack() != "prod" {
+				return nil
+			}
+			pools, ok := args.Resource.Properties["agentPoolProfiles"].ArrayValueOk()
+			if !ok {
+				return nil
+			}
+			for _, pool := range pools {
+				profile, ok := pool.ObjectValueOk()
+				if !ok {
+					continue
+				}
+				if enabled, ok := profile["enableAutoScaling"].BoolValueOk(); !ok || !enabled {
+					manager.ReportViolation("every agent pool must set enableAutoScaling: true in prod", "")
+				}
+			}
+			return nil
+		}),
+	}
+}
+
+func vaultSoftDeleteAndPurgeProtection() *policy.ResourceValidationPolicy {
+	return &policy.ResourceValidationPolicy{
+		Name:             "vault-soft-delete-and-purge-protection",
+		Description:      fmt.Sprintf("Key Vaults must have purge protection and soft delete enabled with retention >= %d days.", minSoftDeleteRetentionDays),
+		EnforcementLevel: policy.Mandatory,
+		ValidateResource: policy.ResourceValidation(func(args *policy.ResourceValidationArgs, manager *policy.ValidationManager) error {
+			if args.Resource.Type != "azure-native:keyvault:Vault" {
+				return nil
+			}
+			properties, ok := args.Resource.Properties["properties"].ObjectValueOk()
+			if !ok {
+				manager.ReportViolation("vault properties are required", "")
+				return nil
+			}
+			if purge, ok := properties["enablePurgeProtection"].BoolValueOk(); !ok || !purge {
+				manager.ReportViolation("vault must set enablePurgeProtection: true", "")
+			}
+			if softDelete, ok := properties["enableSoftDelete"].BoolValueOk(); !ok || !softDelete {
+				manager.ReportViolation("vault must set enableSoftDelete: true", "")
+			}
+			if retention, ok := properties["softDeleteRetentionInDays"].NumberValueOk(); !ok || retention < minSoftDeleteRetentionDays {
+				manager.ReportViolation(
+					fmt.Sprintf("vault softDeleteRetentionInDays must be >= %d", minSoftDeleteRetentionDays), "")
+			}
+			return nil
+		}),
+	}
+}
+
+func cosmosBackupAndFailover() *policy.ResourceValidationPolicy {
+	return &policy.ResourceValidationPolicy{
+		Name:             "cosmos-backup-and-failover",
+		Description:      "Cosmos DB accounts must use continuous backup and automatic failover.",
+		EnforcementLevel: policy.Mandatory,
+		ValidateResource: policy.ResourceValidation(func(args *policy.ResourceValidationArgs, manager *policy.ValidationManager) error {
+			if args.Resource.Type != "azure-native:documentdb:DatabaseAccount" {
+				return nil
+			}
+			if failover, ok := args.Resource.Properties["enableAutomaticFailover"].BoolValueOk(); !ok || !failover {
+				manager.ReportViolation("cosmos account must set enableAutomaticFailover: true", "")
+			}
+			backupPolicy, ok := args.Resource.Properties["backupPolicy"].ObjectValueOk()
+			if !ok {
+				manager.ReportViolation("cosmos account must configure a continuous backup policy", "")
+				return nil
+			}
+			if backupType, ok := backupPolicy["type"].StringValueOk(); !ok || backupType != "Continuous" {
+				manager.ReportViolation("cosmos account backupPolicy.type must be Continuous", "")
+			}
+			return nil
+		}),
+	}
+}